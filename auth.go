@@ -0,0 +1,49 @@
+package rtm
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// AuthService handles communication with the rtm.auth.* methods.
+// See https://www.rememberthemilk.com/services/api/authentication.rtm.
+type AuthService struct {
+	c *Client
+}
+
+// GetFrob returns a frob to be used in AuthenticationURL and, after the user
+// authorizes the application, in GetToken.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.auth.getFrob.rtm.
+func (s *AuthService) GetFrob(ctx context.Context) (string, error) {
+	b, err := s.c.Call(ctx, "rtm.auth.getFrob", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var v struct {
+		XMLName xml.Name `xml:"frob"`
+		Frob    string   `xml:",chardata"`
+	}
+	if err = xml.Unmarshal(b, &v); err != nil {
+		return "", err
+	}
+	return v.Frob, nil
+}
+
+// GetToken exchanges a frob obtained from GetFrob, after the user has
+// authorized the application at AuthenticationURL, for a long-lived auth token.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.auth.getToken.rtm.
+func (s *AuthService) GetToken(ctx context.Context, frob string) (string, error) {
+	b, err := s.c.Call(ctx, "rtm.auth.getToken", Args{"frob": frob})
+	if err != nil {
+		return "", err
+	}
+
+	var v struct {
+		Token string `xml:"token"`
+	}
+	if err = xml.Unmarshal(b, &v); err != nil {
+		return "", err
+	}
+	return v.Token, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"sort"
+	"sync"
 )
 
 const (
@@ -42,26 +44,83 @@ const (
 type Args map[string]string
 
 type Client struct {
-	APIKey      string
-	APISecret   string
-	AuthToken   string
-	HTTPClient  *http.Client
-	DebugLogger func(v ...interface{})
+	APIKey     string
+	APISecret  string
+	HTTPClient *http.Client
+
+	// AuthToken is a static auth token. Deprecated: set Credentials to a
+	// StaticToken (or another CredentialProvider) instead; AuthToken is used
+	// only when Credentials is nil.
+	AuthToken string
+
+	// Credentials, if set, supplies the auth_token for every request and is
+	// invalidated and asked to refresh after error code 98 ("Login failed /
+	// Invalid auth token"). Takes priority over AuthToken.
+	Credentials CredentialProvider
+
+	// Logger, if set, receives redacted request/response dumps and retry
+	// notices. See Logger and NewLogger.
+	Logger Logger
+
+	// Recorder, if set, captures every Transaction returned by mutating calls
+	// made through this Client. See TransactionRecorder.
+	Recorder *TransactionRecorder
+
+	defaultHTTPOnce sync.Once
+	defaultHTTP     *http.Client
+}
+
+// credentials returns the effective CredentialProvider: Credentials if set,
+// otherwise AuthToken wrapped as a StaticToken, or nil if neither is set.
+func (c *Client) credentials() CredentialProvider {
+	if c.Credentials != nil {
+		return c.Credentials
+	}
+	if c.AuthToken != "" {
+		return StaticToken(c.AuthToken)
+	}
+	return nil
+}
+
+// errCodeInvalidAuthToken is RTM's "Login failed / Invalid auth token" error code.
+// See https://www.rememberthemilk.com/services/api/response.rtm.
+const errCodeInvalidAuthToken = 98
+
+// unauthenticatedMethods are rtm.auth.* methods that establish credentials
+// rather than use them, so post must not ask credentials() for a token on
+// these: a CredentialProvider like FrobExchanger calls one of them from
+// inside its own Token method, and attaching a token would re-enter that
+// same call (and, for FrobExchanger, its own mutex) before it has one to give.
+var unauthenticatedMethods = map[string]bool{
+	"rtm.auth.getFrob":  true,
+	"rtm.auth.getToken": true,
 }
 
 func (c *Client) Auth() *AuthService             { return &AuthService{c} }
 func (c *Client) Lists() *ListsService           { return &ListsService{c} }
 func (c *Client) Reflection() *ReflectionService { return &ReflectionService{c} }
+func (c *Client) Sync() *SyncService             { return &SyncService{c} }
 func (c *Client) Tasks() *TasksService           { return &TasksService{c} }
 func (c *Client) Test() *TestService             { return &TestService{c} }
 func (c *Client) Timelines() *TimelinesService   { return &TimelinesService{c} }
 
 // http returns used HTTP client.
+//
+// When HTTPClient is nil, a client wrapping DefaultTransport with Transport's
+// default rate limit and retry behavior is used instead of http.DefaultClient,
+// so programs get RTM's 1 req/sec limit and 5xx/503/504/999 retrying for free.
+// The default client is built at most once, even if http is called
+// concurrently from multiple goroutines (e.g. SyncService.Watch alongside
+// normal calls) — otherwise concurrent first calls could each construct their
+// own Transport, and with it their own token-bucket limiter.
 func (c *Client) http() *http.Client {
-	if c.HTTPClient == nil {
-		return http.DefaultClient
+	if c.HTTPClient != nil {
+		return c.HTTPClient
 	}
-	return c.HTTPClient
+	c.defaultHTTPOnce.Do(func() {
+		c.defaultHTTP = &http.Client{Transport: NewTransport(nil, TransportOptions{MaxRetries: 3, Logger: c.Logger})}
+	})
+	return c.defaultHTTP
 }
 
 // sign adds api_sig to request parameters.
@@ -110,8 +169,14 @@ func (c *Client) post(ctx context.Context, method string, args Args, format stri
 		q.Set("format", format)
 	}
 	q.Set("api_key", c.APIKey)
-	if c.AuthToken != "" {
-		q.Set("auth_token", c.AuthToken)
+	if !unauthenticatedMethods[method] {
+		if cp := c.credentials(); cp != nil {
+			token, err := cp.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			q.Set("auth_token", token)
+		}
 	}
 	c.sign(q)
 
@@ -124,24 +189,24 @@ func (c *Client) post(ctx context.Context, method string, args Args, format stri
 	req = req.WithContext(ctx)
 	req.Header.Set("User-Agent", userAgent)
 
-	if c.DebugLogger != nil {
+	if c.Logger != nil {
 		b, err := httputil.DumpRequestOut(req, true)
 		if err != nil {
 			return nil, err
 		}
-		c.DebugLogger(string(b))
+		c.Logger.LogRequest(req, b)
 	}
 
 	resp, err := c.http().Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 
-		if c.DebugLogger != nil {
+		if c.Logger != nil {
 			b, err := httputil.DumpResponse(resp, true)
 			if err != nil {
 				return nil, err
 			}
-			c.DebugLogger(string(b))
+			c.Logger.LogResponse(resp, b)
 		}
 	}
 	if err != nil {
@@ -155,15 +220,40 @@ func (c *Client) post(ctx context.Context, method string, args Args, format stri
 }
 
 type Error struct {
-	Code int    `xml:"code,attr"`
-	Msg  string `xml:"msg,attr"`
+	Code int    `xml:"code,attr" json:"code,string"`
+	Msg  string `xml:"msg,attr" json:"msg"`
 }
 
 func (e *Error) Error() string {
 	return fmt.Sprintf("%d: %s", e.Code, e.Msg)
 }
 
+// Call invokes method with args and returns the XML payload inside RTM's <rsp>
+// envelope. If the call fails with error code 98 ("Login failed / Invalid auth
+// token") and a CredentialProvider is in use, its token is invalidated and the
+// call is retried once.
 func (c *Client) Call(ctx context.Context, method string, args Args) ([]byte, error) {
+	return c.withTokenRetry(ctx, func() ([]byte, error) {
+		return c.callOnce(ctx, method, args)
+	})
+}
+
+// withTokenRetry runs call, and if it fails with errCodeInvalidAuthToken and a
+// CredentialProvider is in use, invalidates the current token and runs call
+// once more.
+func (c *Client) withTokenRetry(ctx context.Context, call func() ([]byte, error)) ([]byte, error) {
+	b, err := call()
+	if e, ok := err.(*Error); ok && e.Code == errCodeInvalidAuthToken {
+		if cp := c.credentials(); cp != nil {
+			if ierr := cp.Invalidate(ctx); ierr == nil {
+				return call()
+			}
+		}
+	}
+	return b, err
+}
+
+func (c *Client) callOnce(ctx context.Context, method string, args Args) ([]byte, error) {
 	b, err := c.post(ctx, method, args, "")
 	if err != nil {
 		return nil, err
@@ -188,6 +278,51 @@ func (c *Client) Call(ctx context.Context, method string, args Args) ([]byte, er
 	}
 }
 
+// CallJSON is like Call, but requests the REST (JSON) format instead of XML and
+// returns the raw value of "rsp" once its stat/err have been checked. The
+// returned object still contains the "stat" field next to the method's payload.
+//
+// JSON payloads avoid the attribute-heavy, mixed-content shape of RTM's XML
+// responses, which is awkward to unmarshal with encoding/xml. Prefer the typed
+// *JSON service methods (e.g. Tasks().GetListJSON) over calling this directly.
+func (c *Client) CallJSON(ctx context.Context, method string, args Args) (json.RawMessage, error) {
+	b, err := c.withTokenRetry(ctx, func() ([]byte, error) {
+		return c.callJSONOnce(ctx, method, args)
+	})
+	return json.RawMessage(b), err
+}
+
+func (c *Client) callJSONOnce(ctx context.Context, method string, args Args) ([]byte, error) {
+	b, err := c.post(ctx, method, args, "rest")
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp struct {
+		Rsp struct {
+			Stat string `json:"stat"`
+			Err  *Error `json:"err"`
+		} `json:"rsp"`
+	}
+	if err = json.Unmarshal(b, &rsp); err != nil {
+		return nil, err
+	}
+	switch {
+	case rsp.Rsp.Err != nil:
+		return nil, rsp.Rsp.Err
+	case rsp.Rsp.Stat != "ok":
+		return nil, fmt.Errorf("unexpected stat %q", rsp.Rsp.Stat)
+	}
+
+	var inner struct {
+		Rsp json.RawMessage `json:"rsp"`
+	}
+	if err = json.Unmarshal(b, &inner); err != nil {
+		return nil, err
+	}
+	return inner.Rsp, nil
+}
+
 // check interfaces
 var (
 	_ error = (*Error)(nil)
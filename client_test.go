@@ -0,0 +1,67 @@
+package rtm
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newBodyCloser wraps a literal response body for tests that build
+// *http.Response by hand.
+func newBodyCloser(body string) io.ReadCloser {
+	return ioutil.NopCloser(strings.NewReader(body))
+}
+
+// withRESTEndpoint points restEndpoint at an httptest server for the duration
+// of the test.
+func withRESTEndpoint(t *testing.T, rawurl string) {
+	t.Helper()
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", rawurl, err)
+	}
+
+	backup := restEndpoint
+	restEndpoint = *u
+	t.Cleanup(func() { restEndpoint = backup })
+}
+
+func TestClientCallJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("format"); got != "rest" {
+			t.Errorf("format = %q, want %q", got, "rest")
+		}
+		io.WriteString(w, `{"rsp":{"stat":"ok","tasks":{"list":[{"id":"1","taskseries":[]}]}}}`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	raw, err := c.CallJSON(context.Background(), "rtm.tasks.getList", nil)
+	if err != nil {
+		t.Fatalf("CallJSON: %v", err)
+	}
+	if len(raw) == 0 {
+		t.Fatal("CallJSON returned empty payload")
+	}
+}
+
+func TestClientCallJSONError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"rsp":{"stat":"fail","err":{"code":"98","msg":"Login failed"}}}`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	_, err := c.CallJSON(context.Background(), "rtm.tasks.getList", nil)
+	rerr, ok := err.(*Error)
+	if !ok || rerr.Code != errCodeInvalidAuthToken {
+		t.Fatalf("CallJSON err = %v, want *Error with code %d", err, errCodeInvalidAuthToken)
+	}
+}
@@ -0,0 +1,128 @@
+package rtm
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// CredentialProvider supplies the auth_token used on every request and is
+// given a chance to refresh it after RTM reports the current one invalid.
+// Modeled after the credential/session abstraction in docker/distribution's
+// registry/client package.
+type CredentialProvider interface {
+	// Token returns the current auth token.
+	Token(ctx context.Context) (string, error)
+
+	// Invalidate discards any cached token so the next Token call fetches a
+	// fresh one. Called by Client after a request fails with RTM error code 98
+	// ("Login failed / Invalid auth token").
+	Invalidate(ctx context.Context) error
+}
+
+// StaticToken is a CredentialProvider for a token that never changes.
+type StaticToken string
+
+// Token returns the token unchanged.
+func (s StaticToken) Token(ctx context.Context) (string, error) { return string(s), nil }
+
+// Invalidate is a no-op: a StaticToken has nothing to refresh.
+func (s StaticToken) Invalidate(ctx context.Context) error { return nil }
+
+// FrobExchanger is a CredentialProvider for the desktop-app authentication
+// flow: the caller obtains a frob via Client.Auth().GetFrob, sends the user to
+// Client.AuthenticationURL to authorize it, then hands the frob here. The
+// frob->token exchange (rtm.auth.getToken) happens lazily on the first Token
+// call and the resulting token is cached.
+type FrobExchanger struct {
+	Client *Client
+	Frob   string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewFrobExchanger returns a FrobExchanger that exchanges frob for a token
+// using client on first use.
+func NewFrobExchanger(client *Client, frob string) *FrobExchanger {
+	return &FrobExchanger{Client: client, Frob: frob}
+}
+
+// Token returns the cached token, exchanging Frob for one if this is the first call.
+func (f *FrobExchanger) Token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.token != "" {
+		return f.token, nil
+	}
+	token, err := f.Client.Auth().GetToken(ctx, f.Frob)
+	if err != nil {
+		return "", err
+	}
+	f.token = token
+	return token, nil
+}
+
+// Invalidate discards the cached token; the next Token call re-exchanges Frob.
+func (f *FrobExchanger) Invalidate(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = ""
+	return nil
+}
+
+// FileCachedProvider wraps another CredentialProvider and persists the token
+// it returns to a file, so a new process can reuse it instead of re-running
+// the underlying provider's exchange (e.g. FrobExchanger's desktop auth flow).
+type FileCachedProvider struct {
+	Path     string
+	Provider CredentialProvider
+
+	mu sync.Mutex
+}
+
+// NewFileCachedProvider returns a FileCachedProvider backed by provider,
+// caching its token at path.
+func NewFileCachedProvider(path string, provider CredentialProvider) *FileCachedProvider {
+	return &FileCachedProvider{Path: path, Provider: provider}
+}
+
+// Token returns the token cached at Path, falling back to Provider and
+// persisting the result if Path doesn't exist yet.
+func (f *FileCachedProvider) Token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if b, err := ioutil.ReadFile(f.Path); err == nil && len(b) > 0 {
+		return string(b), nil
+	}
+
+	token, err := f.Provider.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err = ioutil.WriteFile(f.Path, []byte(token), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Invalidate removes the cached file and invalidates the underlying provider.
+func (f *FileCachedProvider) Invalidate(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return f.Provider.Invalidate(ctx)
+}
+
+// check interfaces
+var (
+	_ CredentialProvider = StaticToken("")
+	_ CredentialProvider = (*FrobExchanger)(nil)
+	_ CredentialProvider = (*FileCachedProvider)(nil)
+)
@@ -0,0 +1,218 @@
+package rtm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingProvider is a CredentialProvider that counts Invalidate calls and
+// returns a token that changes after each invalidation.
+type countingProvider struct {
+	tokens      []string
+	invalidated int
+}
+
+func (p *countingProvider) Token(ctx context.Context) (string, error) {
+	return p.tokens[p.invalidated], nil
+}
+
+func (p *countingProvider) Invalidate(ctx context.Context) error {
+	p.invalidated++
+	return nil
+}
+
+func TestWithTokenRetryRetriesOnceOnInvalidToken(t *testing.T) {
+	c := &Client{Credentials: &countingProvider{tokens: []string{"old", "new"}}}
+
+	attempts := 0
+	_, err := c.withTokenRetry(context.Background(), func() ([]byte, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, &Error{Code: errCodeInvalidAuthToken, Msg: "Login failed"}
+		}
+		return []byte("ok"), nil
+	})
+	if err != nil {
+		t.Fatalf("withTokenRetry: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	if got := c.Credentials.(*countingProvider).invalidated; got != 1 {
+		t.Fatalf("invalidated = %d, want 1", got)
+	}
+}
+
+func TestWithTokenRetryDoesNotRetryOtherErrors(t *testing.T) {
+	c := &Client{Credentials: &countingProvider{tokens: []string{"old"}}}
+
+	attempts := 0
+	wantErr := errors.New("boom")
+	_, err := c.withTokenRetry(context.Background(), func() ([]byte, error) {
+		attempts++
+		return nil, wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("err = %v, want %v", err, wantErr)
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestWithTokenRetryNoProviderDoesNotRetry(t *testing.T) {
+	c := &Client{}
+
+	attempts := 0
+	_, err := c.withTokenRetry(context.Background(), func() ([]byte, error) {
+		attempts++
+		return nil, &Error{Code: errCodeInvalidAuthToken, Msg: "Login failed"}
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Fatalf("attempts = %d, want 1 (no CredentialProvider to retry with)", attempts)
+	}
+}
+
+func TestStaticToken(t *testing.T) {
+	s := StaticToken("abc123")
+
+	token, err := s.Token(context.Background())
+	if err != nil || token != "abc123" {
+		t.Fatalf("Token() = %q, %v, want %q, nil", token, err, "abc123")
+	}
+	if err := s.Invalidate(context.Background()); err != nil {
+		t.Fatalf("Invalidate(): %v", err)
+	}
+	if token, err := s.Token(context.Background()); err != nil || token != "abc123" {
+		t.Fatalf("Token() after Invalidate = %q, %v, want %q, nil", token, err, "abc123")
+	}
+}
+
+func TestFrobExchangerExchangesOnceAndCaches(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `<rsp stat="ok"><auth><token>the-token</token></auth></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	f := NewFrobExchanger(c, "the-frob")
+
+	for i := 0; i < 3; i++ {
+		token, err := f.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() call %d: %v", i, err)
+		}
+		if token != "the-token" {
+			t.Fatalf("Token() = %q, want %q", token, "the-token")
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("exchange happened %d times, want 1 (later Token calls should hit the cache)", calls)
+	}
+}
+
+func TestFrobExchangerAsClientCredentialsDoesNotDeadlock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<rsp stat="ok"><auth><token>the-token</token></auth></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	c.Credentials = NewFrobExchanger(c, "the-frob")
+
+	done := make(chan struct{})
+	var token string
+	var err error
+	go func() {
+		token, err = c.Credentials.Token(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Token() did not return within 3s: FrobExchanger used as its own Client's Credentials deadlocked")
+	}
+	if err != nil {
+		t.Fatalf("Token(): %v", err)
+	}
+	if token != "the-token" {
+		t.Fatalf("Token() = %q, want %q", token, "the-token")
+	}
+}
+
+func TestFrobExchangerInvalidateForcesReExchange(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `<rsp stat="ok"><auth><token>the-token</token></auth></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	f := NewFrobExchanger(c, "the-frob")
+
+	if _, err := f.Token(context.Background()); err != nil {
+		t.Fatalf("Token(): %v", err)
+	}
+	if err := f.Invalidate(context.Background()); err != nil {
+		t.Fatalf("Invalidate(): %v", err)
+	}
+	if _, err := f.Token(context.Background()); err != nil {
+		t.Fatalf("Token() after Invalidate: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("exchange happened %d times, want 2 (Invalidate should force a re-exchange)", calls)
+	}
+}
+
+func TestFileCachedProviderReadsWritesAndRemovesCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	inner := &countingProvider{tokens: []string{"first", "second"}}
+	f := NewFileCachedProvider(path, inner)
+
+	token, err := f.Token(context.Background())
+	if err != nil || token != "first" {
+		t.Fatalf("Token() = %q, %v, want %q, nil", token, err, "first")
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "first" {
+		t.Fatalf("cache file = %q, %v, want %q, nil", b, err, "first")
+	}
+
+	// A second provider pointed at the same path should read the cache
+	// without ever calling the underlying provider.
+	f2 := NewFileCachedProvider(path, &countingProvider{tokens: []string{"should-not-be-used"}})
+	if token, err := f2.Token(context.Background()); err != nil || token != "first" {
+		t.Fatalf("Token() from cache = %q, %v, want %q, nil", token, err, "first")
+	}
+
+	if err := f.Invalidate(context.Background()); err != nil {
+		t.Fatalf("Invalidate(): %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("cache file still exists after Invalidate: %v", err)
+	}
+	if inner.invalidated != 1 {
+		t.Fatalf("underlying provider invalidated %d times, want 1", inner.invalidated)
+	}
+
+	token, err = f.Token(context.Background())
+	if err != nil || token != "second" {
+		t.Fatalf("Token() after Invalidate = %q, %v, want %q, nil", token, err, "second")
+	}
+}
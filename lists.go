@@ -0,0 +1,41 @@
+package rtm
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// ListsService handles communication with the rtm.lists.* methods.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.lists.getList.rtm.
+type ListsService struct {
+	c *Client
+}
+
+// List is a single RTM list's metadata.
+type List struct {
+	ID       string `xml:"id,attr" json:"id"`
+	Name     string `xml:"name,attr" json:"name"`
+	Deleted  string `xml:"deleted,attr" json:"deleted"`
+	Locked   string `xml:"locked,attr" json:"locked"`
+	Archived string `xml:"archived,attr" json:"archived"`
+	Position string `xml:"position,attr" json:"position"`
+	Smart    string `xml:"smart,attr" json:"smart"`
+}
+
+// GetList returns all of the user's lists.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.lists.getList.rtm.
+func (s *ListsService) GetList(ctx context.Context) ([]List, error) {
+	b, err := s.c.Call(ctx, "rtm.lists.getList", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		XMLName xml.Name `xml:"lists"`
+		Lists   []List   `xml:"list"`
+	}
+	if err = xml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v.Lists, nil
+}
@@ -0,0 +1,86 @@
+package rtm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// Logger receives structured debug events for requests made by Client and
+// retries made by Transport. The default implementation returned by NewLogger
+// redacts credentials before writing anything out, avoiding the footgun where
+// wiring a raw request/response dump to application logs leaks signed
+// credentials (api_sig, auth_token, frob, api_key).
+type Logger interface {
+	// LogRequest is called with the outgoing request and its dump, as
+	// produced by httputil.DumpRequestOut.
+	LogRequest(req *http.Request, dump []byte)
+
+	// LogResponse is called with the received response and its dump, as
+	// produced by httputil.DumpResponse.
+	LogResponse(resp *http.Response, dump []byte)
+
+	// LogRetry is called by Transport before waiting to retry a request.
+	LogRetry(attempt int, err error, wait time.Duration)
+}
+
+// DebugLoggerFunc adapts a plain variadic print function to Logger, for
+// callers migrating from the old Client.DebugLogger field. Dumps passed
+// through it are still redacted before the function is called.
+type DebugLoggerFunc func(v ...interface{})
+
+// LogRequest implements Logger.
+func (f DebugLoggerFunc) LogRequest(req *http.Request, dump []byte) { f(string(redact(dump))) }
+
+// LogResponse implements Logger.
+func (f DebugLoggerFunc) LogResponse(resp *http.Response, dump []byte) { f(string(redact(dump))) }
+
+// LogRetry implements Logger.
+func (f DebugLoggerFunc) LogRetry(attempt int, err error, wait time.Duration) {
+	f(fmt.Sprintf("retry %d after %v: %v", attempt, wait, err))
+}
+
+// writerLogger is the Logger returned by NewLogger.
+type writerLogger struct {
+	w io.Writer
+}
+
+// NewLogger returns a Logger that writes redacted request/response dumps and
+// retry notices to w.
+func NewLogger(w io.Writer) Logger {
+	return &writerLogger{w: w}
+}
+
+func (l *writerLogger) LogRequest(req *http.Request, dump []byte) { l.w.Write(redact(dump)) }
+
+func (l *writerLogger) LogResponse(resp *http.Response, dump []byte) { l.w.Write(redact(dump)) }
+
+func (l *writerLogger) LogRetry(attempt int, err error, wait time.Duration) {
+	fmt.Fprintf(l.w, "retry %d after %v: %v\n", attempt, wait, err)
+}
+
+// redactRe matches "key=value" pairs for RTM's signed/credential parameters,
+// wherever they appear in a dumped URL query string or request/response body.
+var redactRe = regexp.MustCompile(`(api_sig|auth_token|frob|api_key)=[^&\s"]*`)
+
+// redactXMLRe and redactJSONRe match the same credentials, plus the <token> /
+// "token" returned by rtm.auth.getToken, as XML elements or JSON string fields
+// in a response body.
+var (
+	redactXMLRe  = regexp.MustCompile(`<(api_sig|auth_token|frob|api_key|token)>[^<]*<`)
+	redactJSONRe = regexp.MustCompile(`"(api_sig|auth_token|frob|api_key|token)":\s*"[^"]*"`)
+)
+
+func redact(b []byte) []byte {
+	b = redactRe.ReplaceAll(b, []byte("$1=REDACTED"))
+	b = redactXMLRe.ReplaceAll(b, []byte("<$1>REDACTED<"))
+	return redactJSONRe.ReplaceAll(b, []byte(`"$1":"REDACTED"`))
+}
+
+// check interfaces
+var (
+	_ Logger = DebugLoggerFunc(nil)
+	_ Logger = (*writerLogger)(nil)
+)
@@ -0,0 +1,30 @@
+package rtm
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "GET /services/rest/?api_key=abc123&method=rtm.test.echo&api_sig=deadbeef HTTP/1.1",
+			want: "GET /services/rest/?api_key=REDACTED&method=rtm.test.echo&api_sig=REDACTED HTTP/1.1",
+		},
+		{
+			in:   "POST /services/rest/?frob=f00&auth_token=s3cr3t&v=2 HTTP/1.1\nHost: api.rememberthemilk.com",
+			want: "POST /services/rest/?frob=REDACTED&auth_token=REDACTED&v=2 HTTP/1.1\nHost: api.rememberthemilk.com",
+		},
+		{
+			in:   "no secrets here",
+			want: "no secrets here",
+		},
+	}
+
+	for _, tc := range cases {
+		got := string(redact([]byte(tc.in)))
+		if got != tc.want {
+			t.Errorf("redact(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package rtm
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// ReflectionService handles communication with the rtm.reflection.* methods.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.reflection.getMethods.rtm.
+type ReflectionService struct {
+	c *Client
+}
+
+// Method is a single API method name, as returned by GetMethods.
+type Method struct {
+	Name string `xml:"name,attr" json:"name"`
+}
+
+// GetMethods lists all API methods available.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.reflection.getMethods.rtm.
+func (s *ReflectionService) GetMethods(ctx context.Context) ([]Method, error) {
+	b, err := s.c.Call(ctx, "rtm.reflection.getMethods", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		XMLName xml.Name `xml:"methods"`
+		Methods []Method `xml:"method"`
+	}
+	if err = xml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v.Methods, nil
+}
@@ -0,0 +1,180 @@
+package rtm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SyncService implements incremental synchronization of tasks using the
+// last_sync parameter of rtm.tasks.getList.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.tasks.getList.rtm.
+type SyncService struct {
+	c *Client
+}
+
+// SnapshotSeries is a TaskSeries together with the ID of the list it
+// currently belongs to.
+type SnapshotSeries struct {
+	ListID string `json:"list_id"`
+	TaskSeries
+}
+
+// Snapshot is a local copy of a caller's task series as of LastSync, suitable
+// for persisting between runs and passing back into SyncService.Pull.
+type Snapshot struct {
+	LastSync time.Time
+	Series   map[string]SnapshotSeries // keyed by TaskSeries.ID
+
+	// Diff describes the task series added, modified or deleted by the Pull
+	// that produced this Snapshot, relative to the Snapshot passed into it.
+	Diff Diff
+}
+
+// Diff describes the task series IDs added, modified or deleted by a Pull.
+type Diff struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+func (d *Diff) empty() bool {
+	return len(d.Added) == 0 && len(d.Modified) == 0 && len(d.Deleted) == 0
+}
+
+// snapshotJSON is Snapshot's on-disk shape: Series is easier to marshal as a
+// slice than as a map keyed by a field already present on each value.
+type snapshotJSON struct {
+	LastSync time.Time        `json:"last_sync"`
+	Series   []SnapshotSeries `json:"series"`
+	Diff     Diff             `json:"diff"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	aux := snapshotJSON{LastSync: s.LastSync, Series: make([]SnapshotSeries, 0, len(s.Series)), Diff: s.Diff}
+	for _, ss := range s.Series {
+		aux.Series = append(aux.Series, ss)
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Snapshot) UnmarshalJSON(b []byte) error {
+	var aux snapshotJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	s.LastSync = aux.LastSync
+	s.Diff = aux.Diff
+	s.Series = make(map[string]SnapshotSeries, len(aux.Series))
+	for _, ss := range aux.Series {
+		s.Series[ss.ID] = ss
+	}
+	return nil
+}
+
+// Pull fetches task series changed since prev.LastSync, or a full sync if
+// prev is nil or its LastSync is zero, and returns the resulting Snapshot,
+// whose Diff field describes the changes applied relative to prev.
+func (s *SyncService) Pull(ctx context.Context, prev *Snapshot) (*Snapshot, error) {
+	args := Args{}
+	if prev != nil && !prev.LastSync.IsZero() {
+		args["last_sync"] = prev.LastSync.UTC().Format(time.RFC3339)
+	}
+
+	lists, err := s.c.Tasks().GetList(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &Snapshot{LastSync: time.Now(), Series: make(map[string]SnapshotSeries)}
+	if prev != nil {
+		for id, ss := range prev.Series {
+			next.Series[id] = ss
+		}
+	}
+	next.Diff = applyLists(next.Series, lists)
+
+	return next, nil
+}
+
+// applyLists merges lists into series in place and returns the resulting Diff.
+func applyLists(series map[string]SnapshotSeries, lists []TaskList) Diff {
+	var diff Diff
+	for _, list := range lists {
+		for _, ts := range list.TaskSeries {
+			_, existed := series[ts.ID]
+
+			if seriesDeleted(ts) {
+				delete(series, ts.ID)
+				if existed {
+					diff.Deleted = append(diff.Deleted, ts.ID)
+				}
+				continue
+			}
+
+			series[ts.ID] = SnapshotSeries{ListID: list.ID, TaskSeries: ts}
+			if existed {
+				diff.Modified = append(diff.Modified, ts.ID)
+			} else {
+				diff.Added = append(diff.Added, ts.ID)
+			}
+		}
+	}
+	return diff
+}
+
+// seriesDeleted reports whether series has no live occurrence left. A
+// recurring task keeps its completed/deleted history alongside the current
+// occurrence in the same series, so the series as a whole is only gone once
+// every Task in it is deleted — a single deleted occurrence must not purge an
+// otherwise-live series.
+func seriesDeleted(series TaskSeries) bool {
+	if len(series.Tasks) == 0 {
+		return false
+	}
+	for _, t := range series.Tasks {
+		if t.Deleted == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch calls Pull every interval, starting from prev (nil for a full initial
+// sync, or a Snapshot restored from persistence to resume where a previous
+// run left off), and emits the resulting Snapshot on the returned channel
+// whenever its Diff is non-empty. It stops and closes the channel when ctx is
+// done. The interval only paces how often Pull is called; the actual request
+// rate is still bounded by Client's Transport.
+func (s *SyncService) Watch(ctx context.Context, prev *Snapshot, interval time.Duration) <-chan *Snapshot {
+	ch := make(chan *Snapshot)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			next, err := s.Pull(ctx, prev)
+			if err == nil {
+				prev = next
+				if !next.Diff.empty() {
+					select {
+					case ch <- next:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
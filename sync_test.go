@@ -0,0 +1,219 @@
+package rtm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSeriesDeleted(t *testing.T) {
+	cases := []struct {
+		name   string
+		series TaskSeries
+		want   bool
+	}{
+		{
+			name:   "no tasks",
+			series: TaskSeries{ID: "1"},
+			want:   false,
+		},
+		{
+			name:   "single live task",
+			series: TaskSeries{ID: "1", Tasks: []Task{{ID: "t1"}}},
+			want:   false,
+		},
+		{
+			name:   "single deleted task",
+			series: TaskSeries{ID: "1", Tasks: []Task{{ID: "t1", Deleted: "2026-01-01T00:00:00Z"}}},
+			want:   true,
+		},
+		{
+			name: "recurring task: one deleted occurrence, one still live",
+			series: TaskSeries{ID: "1", Tasks: []Task{
+				{ID: "t1", Deleted: "2026-01-01T00:00:00Z"},
+				{ID: "t2"},
+			}},
+			want: false,
+		},
+		{
+			name: "recurring task: every occurrence deleted",
+			series: TaskSeries{ID: "1", Tasks: []Task{
+				{ID: "t1", Deleted: "2026-01-01T00:00:00Z"},
+				{ID: "t2", Deleted: "2026-01-02T00:00:00Z"},
+			}},
+			want: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := seriesDeleted(tc.series); got != tc.want {
+				t.Errorf("seriesDeleted(%+v) = %v, want %v", tc.series, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyLists(t *testing.T) {
+	series := map[string]SnapshotSeries{
+		"existing": {ListID: "L1", TaskSeries: TaskSeries{ID: "existing", Tasks: []Task{{ID: "t0"}}}},
+		"gone":     {ListID: "L1", TaskSeries: TaskSeries{ID: "gone", Tasks: []Task{{ID: "t1"}}}},
+	}
+
+	lists := []TaskList{
+		{
+			ID: "L1",
+			TaskSeries: []TaskSeries{
+				{ID: "existing", Tasks: []Task{{ID: "t0", Priority: "1"}}},               // modified
+				{ID: "new", Tasks: []Task{{ID: "t2"}}},                                   // added
+				{ID: "gone", Tasks: []Task{{ID: "t1", Deleted: "2026-01-01T00:00:00Z"}}}, // deleted
+			},
+		},
+	}
+
+	diff := applyLists(series, lists)
+
+	if len(diff.Added) != 1 || diff.Added[0] != "new" {
+		t.Errorf("Added = %v, want [new]", diff.Added)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0] != "existing" {
+		t.Errorf("Modified = %v, want [existing]", diff.Modified)
+	}
+	if len(diff.Deleted) != 1 || diff.Deleted[0] != "gone" {
+		t.Errorf("Deleted = %v, want [gone]", diff.Deleted)
+	}
+	if _, ok := series["gone"]; ok {
+		t.Error("deleted series still present in the snapshot map")
+	}
+	if _, ok := series["new"]; !ok {
+		t.Error("added series missing from the snapshot map")
+	}
+}
+
+func TestSnapshotJSONRoundTrip(t *testing.T) {
+	want := &Snapshot{
+		Series: map[string]SnapshotSeries{
+			"1": {ListID: "L1", TaskSeries: TaskSeries{ID: "1", Name: "Buy milk"}},
+		},
+		Diff: Diff{Added: []string{"1"}},
+	}
+
+	b, err := want.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got Snapshot
+	if err := got.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if len(got.Series) != 1 || got.Series["1"].Name != "Buy milk" {
+		t.Errorf("Series round-trip mismatch: %+v", got.Series)
+	}
+	if len(got.Diff.Added) != 1 || got.Diff.Added[0] != "1" {
+		t.Errorf("Diff round-trip mismatch: %+v", got.Diff)
+	}
+}
+
+func TestPullOmitsLastSyncOnFirstCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("last_sync"); got != "" {
+			t.Errorf("last_sync = %q, want empty for a nil prev", got)
+		}
+		io.WriteString(w, `<rsp stat="ok"><tasks><list id="L1"><taskseries id="new" name="Fresh">`+
+			`<task id="t1"/></taskseries></list></tasks></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	snap, err := c.Sync().Pull(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if len(snap.Diff.Added) != 1 || snap.Diff.Added[0] != "new" {
+		t.Fatalf("Diff.Added = %v, want [new]", snap.Diff.Added)
+	}
+	if _, ok := snap.Series["new"]; !ok {
+		t.Fatal("new series missing from the resulting Snapshot")
+	}
+}
+
+func TestPullSetsLastSyncFromPrev(t *testing.T) {
+	prevSync := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := prevSync.Format(time.RFC3339)
+		if got := r.URL.Query().Get("last_sync"); got != want {
+			t.Errorf("last_sync = %q, want %q", got, want)
+		}
+		io.WriteString(w, `<rsp stat="ok"><tasks></tasks></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	prev := &Snapshot{LastSync: prevSync, Series: map[string]SnapshotSeries{
+		"existing": {ListID: "L1", TaskSeries: TaskSeries{ID: "existing"}},
+	}}
+
+	snap, err := c.Sync().Pull(context.Background(), prev)
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if !snap.Diff.empty() {
+		t.Fatalf("Diff = %+v, want empty (server returned no lists)", snap.Diff)
+	}
+	if _, ok := snap.Series["existing"]; !ok {
+		t.Fatal("Pull dropped a series untouched by the server's response")
+	}
+}
+
+func TestWatchEmitsOnChangeAndClosesOnCtxDone(t *testing.T) {
+	// First poll returns a new series (non-empty diff, should be emitted);
+	// every poll after that returns the same series unchanged (empty diff,
+	// should not be emitted).
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			io.WriteString(w, `<rsp stat="ok"><tasks><list id="L1"><taskseries id="s1" name="Only">`+
+				`<task id="t1"/></taskseries></list></tasks></rsp>`)
+			return
+		}
+		io.WriteString(w, `<rsp stat="ok"><tasks></tasks></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 175*time.Millisecond)
+	defer cancel()
+
+	ch := c.Sync().Watch(ctx, nil, 50*time.Millisecond)
+
+	var emitted []*Snapshot
+	for snap := range ch {
+		emitted = append(emitted, snap)
+	}
+
+	if len(emitted) != 1 {
+		t.Fatalf("emitted %d snapshots, want 1 (only the poll with a non-empty diff)", len(emitted))
+	}
+	if len(emitted[0].Diff.Added) != 1 || emitted[0].Diff.Added[0] != "s1" {
+		t.Fatalf("emitted snapshot Diff.Added = %v, want [s1]", emitted[0].Diff.Added)
+	}
+	if calls < 2 {
+		t.Fatalf("server saw %d calls, want at least 2 (Watch should keep polling after the first unchanged result)", calls)
+	}
+
+	// The channel must be closed once ctx is done, not left open.
+	if _, ok := <-ch; ok {
+		t.Fatal("channel still open after ctx was done")
+	}
+}
@@ -0,0 +1,107 @@
+package rtm
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+)
+
+// TasksService handles communication with the rtm.tasks.* methods.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.tasks.getList.rtm.
+type TasksService struct {
+	c *Client
+}
+
+// Task represents a single due/completion record within a TaskSeries.
+type Task struct {
+	ID        string `xml:"id,attr" json:"id"`
+	Due       string `xml:"due,attr" json:"due"`
+	Added     string `xml:"added,attr" json:"added"`
+	Completed string `xml:"completed,attr" json:"completed"`
+	Deleted   string `xml:"deleted,attr" json:"deleted"`
+	Priority  string `xml:"priority,attr" json:"priority"`
+	Postponed string `xml:"postponed,attr" json:"postponed"`
+	Estimate  string `xml:"estimate,attr" json:"estimate"`
+}
+
+// TaskSeries is a named task and its history of Task due/completion records.
+type TaskSeries struct {
+	ID       string `xml:"id,attr" json:"id"`
+	Created  string `xml:"created,attr" json:"created"`
+	Modified string `xml:"modified,attr" json:"modified"`
+	Name     string `xml:"name,attr" json:"name"`
+	Source   string `xml:"source,attr" json:"source"`
+	URL      string `xml:"url,attr" json:"url"`
+	Tasks    []Task `xml:"task" json:"task"`
+}
+
+// TaskList is a single RTM list and the task series it contains.
+type TaskList struct {
+	ID         string       `xml:"id,attr" json:"id"`
+	TaskSeries []TaskSeries `xml:"taskseries" json:"taskseries"`
+}
+
+// GetList returns the lists, task series and tasks matching the optional filter in args["filter"].
+// See https://www.rememberthemilk.com/services/api/methods/rtm.tasks.getList.rtm.
+func (s *TasksService) GetList(ctx context.Context, args Args) ([]TaskList, error) {
+	b, err := s.c.Call(ctx, "rtm.tasks.getList", args)
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		XMLName xml.Name   `xml:"tasks"`
+		Lists   []TaskList `xml:"list"`
+	}
+	if err = xml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v.Lists, nil
+}
+
+// GetListJSON is like GetList, but uses Client.CallJSON to avoid encoding/xml entirely.
+func (s *TasksService) GetListJSON(ctx context.Context, args Args) ([]TaskList, error) {
+	raw, err := s.c.CallJSON(ctx, "rtm.tasks.getList", args)
+	if err != nil {
+		return nil, err
+	}
+
+	var v struct {
+		Tasks struct {
+			Lists []TaskList `json:"list"`
+		} `json:"tasks"`
+	}
+	if err = json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v.Tasks.Lists, nil
+}
+
+// Add creates a new task series named name on the given list within timeline,
+// returning the write Transaction and the created TaskSeries.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.tasks.add.rtm.
+func (s *TasksService) Add(ctx context.Context, timeline, listID, name string, args Args) (Transaction, TaskSeries, error) {
+	a := Args{"timeline": timeline, "name": name}
+	if listID != "" {
+		a["list_id"] = listID
+	}
+	for k, v := range args {
+		a[k] = v
+	}
+
+	tx, wrapped, err := s.c.callMutating(ctx, "rtm.tasks.add", a)
+	if err != nil {
+		return Transaction{}, TaskSeries{}, err
+	}
+
+	var v struct {
+		List TaskList `xml:"list"`
+	}
+	if err = xml.Unmarshal(wrapped, &v); err != nil {
+		return Transaction{}, TaskSeries{}, err
+	}
+	if len(v.List.TaskSeries) == 0 {
+		return tx, TaskSeries{}, nil
+	}
+	return tx, v.List.TaskSeries[0], nil
+}
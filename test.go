@@ -0,0 +1,16 @@
+package rtm
+
+import "context"
+
+// TestService handles communication with the rtm.test.* methods, used to
+// check connectivity and credentials without side effects.
+type TestService struct {
+	c *Client
+}
+
+// Login verifies that the client's credentials are accepted by RTM.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.test.login.rtm.
+func (s *TestService) Login(ctx context.Context) error {
+	_, err := s.c.Call(ctx, "rtm.test.login", nil)
+	return err
+}
@@ -0,0 +1,40 @@
+package rtm
+
+import (
+	"context"
+	"encoding/xml"
+)
+
+// TimelinesService handles communication with the rtm.timelines.* methods.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.timelines.create.rtm.
+type TimelinesService struct {
+	c *Client
+}
+
+// Create starts a new timeline and returns its ID. Every mutating call requires
+// a timeline so RTM can group its writes for undo.
+func (s *TimelinesService) Create(ctx context.Context) (string, error) {
+	b, err := s.c.Call(ctx, "rtm.timelines.create", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var v struct {
+		XMLName  xml.Name `xml:"timeline"`
+		Timeline string   `xml:",chardata"`
+	}
+	if err = xml.Unmarshal(b, &v); err != nil {
+		return "", err
+	}
+	return v.Timeline, nil
+}
+
+// Undo reverts the transaction txID made within timeline.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.transactions.undo.rtm.
+func (s *TimelinesService) Undo(ctx context.Context, timeline, txID string) error {
+	_, err := s.c.Call(ctx, "rtm.transactions.undo", Args{
+		"timeline":       timeline,
+		"transaction_id": txID,
+	})
+	return err
+}
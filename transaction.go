@@ -0,0 +1,74 @@
+package rtm
+
+import (
+	"context"
+	"encoding/xml"
+	"sync"
+)
+
+// Transaction is the <transaction id="..." undoable="1"/> element RTM attaches
+// to the response of every mutating (write) method.
+// See https://www.rememberthemilk.com/services/api/methods/rtm.tasks.add.rtm.
+type Transaction struct {
+	ID       string `xml:"id,attr" json:"id"`
+	Undoable bool   `xml:"undoable,attr" json:"undoable"`
+}
+
+// TransactionRecorder records every Transaction produced by mutating calls
+// made through a Client it is attached to (see Client.Recorder), keyed by
+// timeline. A caller can use this to implement multi-step undo/redo in a UI
+// without threading Transaction return values through every call site.
+type TransactionRecorder struct {
+	mu  sync.Mutex
+	txs map[string][]Transaction
+}
+
+// NewTransactionRecorder returns an empty TransactionRecorder.
+func NewTransactionRecorder() *TransactionRecorder {
+	return &TransactionRecorder{txs: make(map[string][]Transaction)}
+}
+
+// Record appends tx to the history for timeline.
+func (r *TransactionRecorder) Record(timeline string, tx Transaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs[timeline] = append(r.txs[timeline], tx)
+}
+
+// Transactions returns the recorded history for timeline, oldest first.
+func (r *TransactionRecorder) Transactions(timeline string) []Transaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Transaction, len(r.txs[timeline]))
+	copy(out, r.txs[timeline])
+	return out
+}
+
+// callMutating invokes a write method, parses its leading <transaction> element,
+// and records it on c.Recorder (if any) keyed by args["timeline"]. It returns the
+// parsed Transaction alongside the response body wrapped in a synthetic root
+// element, so callers can unmarshal the method-specific payload that follows
+// the transaction (e.g. <list>...</list>) in the same document.
+func (c *Client) callMutating(ctx context.Context, method string, args Args) (Transaction, []byte, error) {
+	b, err := c.Call(ctx, method, args)
+	if err != nil {
+		return Transaction{}, nil, err
+	}
+
+	wrapped := make([]byte, 0, len(b)+7)
+	wrapped = append(wrapped, "<x>"...)
+	wrapped = append(wrapped, b...)
+	wrapped = append(wrapped, "</x>"...)
+
+	var env struct {
+		Transaction Transaction `xml:"transaction"`
+	}
+	if err = xml.Unmarshal(wrapped, &env); err != nil {
+		return Transaction{}, nil, err
+	}
+
+	if c.Recorder != nil {
+		c.Recorder.Record(args["timeline"], env.Transaction)
+	}
+	return env.Transaction, wrapped, nil
+}
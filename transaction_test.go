@@ -0,0 +1,53 @@
+package rtm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallMutatingRecordsTransaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<rsp stat="ok"><transaction id="123" undoable="1"/><list id="L1"><taskseries id="1" name="Buy milk"></taskseries></list></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	rec := NewTransactionRecorder()
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client(), Recorder: rec}
+
+	tx, wrapped, err := c.callMutating(context.Background(), "rtm.tasks.add", Args{"timeline": "t1", "name": "Buy milk"})
+	if err != nil {
+		t.Fatalf("callMutating: %v", err)
+	}
+	if tx.ID != "123" || !tx.Undoable {
+		t.Fatalf("tx = %+v, want {ID: 123, Undoable: true}", tx)
+	}
+	if len(wrapped) == 0 {
+		t.Fatal("callMutating returned no wrapped payload")
+	}
+
+	got := rec.Transactions("t1")
+	if len(got) != 1 || got[0] != tx {
+		t.Fatalf("recorded transactions for t1 = %+v, want [%+v]", got, tx)
+	}
+}
+
+func TestTasksAddParsesTaskSeries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<rsp stat="ok"><transaction id="1" undoable="1"/><list id="L1"><taskseries id="99" name="Buy milk"><task id="t1"/></taskseries></list></rsp>`)
+	}))
+	defer srv.Close()
+	withRESTEndpoint(t, srv.URL)
+
+	c := &Client{APIKey: "key", APISecret: "secret", HTTPClient: srv.Client()}
+	_, series, err := c.Tasks().Add(context.Background(), "t1", "L1", "Buy milk", nil)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if series.ID != "99" || series.Name != "Buy milk" {
+		t.Fatalf("series = %+v, want ID=99 Name=\"Buy milk\"", series)
+	}
+}
@@ -0,0 +1,288 @@
+package rtm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RTM enforces a per-key rate limit of roughly 1 request/second.
+// See https://www.rememberthemilk.com/services/api/.
+const defaultRateLimit = time.Second
+
+// Error codes that mean "try again later" rather than "this call is wrong".
+// See https://www.rememberthemilk.com/services/api/response.rtm.
+const (
+	errCodeServiceUnavailable = 503
+	errCodeFormatUnavailable  = 504
+	errCodeRateLimitExceeded  = 999
+)
+
+// TransportOptions configures a Transport.
+type TransportOptions struct {
+	// RateLimit is the interval at which the one-token bucket is refilled, so
+	// requests start at most once per RateLimit on average; a banked token can
+	// let two requests start close together across a refill. Zero uses
+	// defaultRateLimit.
+	RateLimit time.Duration
+
+	// MaxRetries is the maximum number of retries for a request that fails with a
+	// retryable HTTP status or RTM error code. Zero disables retrying.
+	MaxRetries int
+
+	// MinBackoff is the base of the exponential backoff between retries and
+	// MaxBackoff caps it; each wait is drawn at random below that bound (see
+	// backoff). Zero values default to 500ms and 30s respectively.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Logger, if set, has LogRetry called before each retry wait.
+	Logger Logger
+}
+
+// Transport is an http.RoundTripper that rate-limits and retries requests made
+// against the RTM API. It is modeled after the small, composable RoundTripper
+// wrappers in docker/distribution's registry/client/transport.go: a Transport
+// wraps a Base RoundTripper and adds a single concern, so it can be layered
+// with other RoundTrippers (auth, logging, ...) supplied by the caller.
+//
+// Client uses a Transport with default options except MaxRetries, which it
+// sets to 3, when HTTPClient is nil. Callers
+// who supply their own HTTPClient can opt in by wrapping its Transport with
+// NewTransport.
+type Transport struct {
+	Base    http.RoundTripper
+	Options TransportOptions
+
+	initOnce  sync.Once
+	closeOnce sync.Once
+	tokens    chan struct{}
+	ticker    *time.Ticker
+	stop      chan struct{}
+}
+
+// NewTransport wraps base (http.DefaultTransport if nil) with RTM's rate limiter
+// and retryer.
+func NewTransport(base http.RoundTripper, opts TransportOptions) *Transport {
+	t := &Transport{Base: base, Options: opts}
+	t.init()
+	return t
+}
+
+// init fills in defaults and starts the token bucket. It is idempotent and
+// safe to call from RoundTrip, so a Transport built by hand (bypassing
+// NewTransport) initializes itself on first use instead of hanging in wait
+// (nil tokens channel) or panicking in backoff (zero-range Options).
+func (t *Transport) init() {
+	t.initOnce.Do(func() {
+		if t.Base == nil {
+			t.Base = http.DefaultTransport
+		}
+		if t.Options.RateLimit == 0 {
+			t.Options.RateLimit = defaultRateLimit
+		}
+		if t.Options.MinBackoff == 0 {
+			t.Options.MinBackoff = 500 * time.Millisecond
+		}
+		if t.Options.MaxBackoff == 0 {
+			t.Options.MaxBackoff = 30 * time.Second
+		}
+
+		t.tokens = make(chan struct{}, 1)
+		t.tokens <- struct{}{}
+		t.ticker = time.NewTicker(t.Options.RateLimit)
+		t.stop = make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-t.ticker.C:
+					select {
+					case t.tokens <- struct{}{}:
+					default:
+					}
+				case <-t.stop:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Close stops the token-bucket ticker goroutine started by init. It is safe
+// to call on a Transport that was never used (the goroutine was never
+// started) and safe to call more than once. A Transport that isn't kept for
+// the life of the process (e.g. one built per request or per tenant) should
+// be Closed once it's done to avoid leaking the ticker and its goroutine.
+func (t *Transport) Close() error {
+	t.closeOnce.Do(func() {
+		if t.ticker != nil {
+			t.ticker.Stop()
+		}
+		if t.stop != nil {
+			close(t.stop)
+		}
+	})
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.init()
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.Base.RoundTrip(req)
+		if err == nil && !isRetryableStatus(resp.StatusCode) && !hasRetryableErrorCode(resp) {
+			return resp, nil
+		}
+		if err != nil || attempt >= t.Options.MaxRetries {
+			return resp, err
+		}
+
+		wait := retryAfter(resp)
+		if wait == 0 {
+			wait = backoff(attempt, t.Options.MinBackoff, t.Options.MaxBackoff)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if t.Options.Logger != nil {
+			t.Options.Logger.LogRetry(attempt, err, wait)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (t *Transport) wait(ctx context.Context) error {
+	select {
+	case <-t.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isRetryableStatus(code int) bool {
+	return code >= 500 && code < 600
+}
+
+// hasRetryableErrorCode peeks at an otherwise-200 RTM response to see if it
+// carries one of the retryable RTM error codes (service unavailable, format
+// unavailable, rate limit exceeded). It leaves resp.Body readable for callers
+// by restoring it after peeking.
+func hasRetryableErrorCode(resp *http.Response) bool {
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		return false
+	}
+	code, ok := peekErrorCode(resp)
+	if !ok {
+		return false
+	}
+	switch code {
+	case errCodeServiceUnavailable, errCodeFormatUnavailable, errCodeRateLimitExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// peekErrorCode reads resp.Body far enough to extract an RTM <err code="..."/>
+// or {"err":{"code":"..."}} payload, then restores it so the caller can still
+// read the full body.
+func peekErrorCode(resp *http.Response) (int, bool) {
+	if resp.Body == nil {
+		return 0, false
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return 0, false
+	}
+
+	var xrsp struct {
+		Err *Error `xml:"err"`
+	}
+	if xml.Unmarshal(b, &xrsp) == nil && xrsp.Err != nil {
+		return xrsp.Err.Code, true
+	}
+
+	var jrsp struct {
+		Rsp struct {
+			Err *Error `json:"err"`
+		} `json:"rsp"`
+	}
+	if json.Unmarshal(b, &jrsp) == nil && jrsp.Rsp.Err != nil {
+		return jrsp.Rsp.Err.Code, true
+	}
+
+	return 0, false
+}
+
+// retryAfter returns the duration requested by a Retry-After header, or zero
+// if absent or unparseable.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// backoff returns an exponential backoff duration with full jitter: a random
+// duration in [0, d), where d is min doubled attempt times and capped at max.
+// The result can be shorter than min.
+// A non-positive max (e.g. a hand-built Transport with a zero-value Options)
+// falls back to the package default instead of panicking in rand.Int63n.
+func backoff(attempt int, min, max time.Duration) time.Duration {
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	d := min << uint(attempt)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// sleep waits for d, returning early with ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// check interfaces
+var (
+	_ http.RoundTripper = (*Transport)(nil)
+	_ io.Closer         = (*Transport)(nil)
+)
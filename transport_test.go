@@ -0,0 +1,291 @@
+package rtm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackoffBounded(t *testing.T) {
+	min := 100 * time.Millisecond
+	max := time.Second
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoff(attempt, min, max)
+		if d < 0 || d > max {
+			t.Fatalf("attempt %d: backoff %v out of [0, %v]", attempt, d, max)
+		}
+	}
+}
+
+func TestBackoffZeroRangeDoesNotPanic(t *testing.T) {
+	// A hand-built Transport (bypassing NewTransport) has zero-value Options;
+	// backoff must fall back to a sane default instead of panicking in
+	// rand.Int63n(0).
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("backoff panicked with zero-value min/max: %v", r)
+		}
+	}()
+	backoff(0, 0, 0)
+}
+
+func TestTransportZeroValueDoesNotHang(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// Bypass NewTransport entirely to exercise the defensive init path.
+	tr := &Transport{}
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestPeekErrorCodeXML(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       newBodyCloser(`<rsp stat="fail"><err code="999" msg="Rate limit exceeded"/></rsp>`),
+	}
+	code, ok := peekErrorCode(resp)
+	if !ok || code != 999 {
+		t.Fatalf("peekErrorCode(xml) = %d, %v; want 999, true", code, ok)
+	}
+	// body must still be readable afterwards
+	if resp.Body == nil {
+		t.Fatal("peekErrorCode consumed resp.Body")
+	}
+}
+
+func TestPeekErrorCodeJSON(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       newBodyCloser(`{"rsp":{"stat":"fail","err":{"code":"503","msg":"Service unavailable"}}}`),
+	}
+	code, ok := peekErrorCode(resp)
+	if !ok || code != 503 {
+		t.Fatalf("peekErrorCode(json) = %d, %v; want 503, true", code, ok)
+	}
+}
+
+func TestRoundTripRetriesOnRetryableStatus(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tr := NewTransport(nil, TransportOptions{
+		RateLimit:  time.Millisecond,
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	})
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3 (2 failures + 1 success)", calls)
+	}
+}
+
+func TestRoundTripStopsAtMaxRetries(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr := NewTransport(nil, TransportOptions{
+		RateLimit:  time.Millisecond,
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	})
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("StatusCode = %d, want 503", resp.StatusCode)
+	}
+	if want := 3; calls != want { // the initial attempt plus MaxRetries retries
+		t.Fatalf("calls = %d, want %d", calls, want)
+	}
+}
+
+func TestRoundTripRespectsRetryAfter(t *testing.T) {
+	var calls int
+	var firstCall, secondCall time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			firstCall = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		secondCall = time.Now()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// MinBackoff/MaxBackoff are set far below the Retry-After value, so a
+	// ~1s gap between calls can only be explained by the header being honored.
+	tr := NewTransport(nil, TransportOptions{
+		RateLimit:  time.Millisecond,
+		MaxRetries: 1,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	})
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if gap := secondCall.Sub(firstCall); gap < 900*time.Millisecond {
+		t.Fatalf("retry happened after %v, want >= ~1s (Retry-After: 1 should have been honored)", gap)
+	}
+}
+
+func TestRoundTripCtxCancellationAbortsWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	// A long RateLimit means the single initial token is consumed by the
+	// first request; the second request's wait() blocks on the token bucket
+	// until the next tick, which is far later than the ctx we cancel below.
+	tr := NewTransport(nil, TransportOptions{RateLimit: time.Hour})
+	client := &http.Client{Transport: tr}
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("first Get: %v", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req2, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.Do(req2.WithContext(ctx))
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("expected an error from a request stuck waiting on an exhausted token bucket past its ctx deadline")
+	}
+	if elapsed > time.Second {
+		t.Fatalf("request took %v to abort, want well under 1s (ctx cancellation should abort wait promptly)", elapsed)
+	}
+}
+
+func TestRoundTripCtxCancellationAbortsBackoffSleep(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr := NewTransport(nil, TransportOptions{
+		RateLimit:  time.Millisecond,
+		MaxRetries: 10,
+		MinBackoff: time.Hour,
+		MaxBackoff: time.Hour,
+	})
+	client := &http.Client{Transport: tr}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.Do(req.WithContext(ctx))
+	elapsed := time.Since(start)
+	if err == nil {
+		t.Fatal("expected an error: ctx should have expired during the hour-long backoff sleep")
+	}
+	if elapsed > time.Second {
+		t.Fatalf("request took %v to abort, want well under 1s (ctx cancellation should abort the backoff sleep promptly)", elapsed)
+	}
+}
+
+func TestTransportCloseStopsTicker(t *testing.T) {
+	tr := NewTransport(nil, TransportOptions{RateLimit: 5 * time.Millisecond})
+
+	// Consume the one token init() preloads.
+	if err := tr.wait(context.Background()); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	tr.Close()
+	time.Sleep(20 * time.Millisecond) // long enough for several ticks, had the ticker still been running
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tr.wait(ctx); err == nil {
+		t.Fatal("wait() got a token after Close, want the ticker to have stopped refilling")
+	}
+}
+
+func TestTransportCloseIsSafeOnUnusedOrRepeated(t *testing.T) {
+	tr := &Transport{}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close on an un-init'd Transport: %v", err)
+	}
+
+	tr2 := NewTransport(nil, TransportOptions{})
+	if err := tr2.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := tr2.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestPeekErrorCodeOK(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       newBodyCloser(`<rsp stat="ok"></rsp>`),
+	}
+	if _, ok := peekErrorCode(resp); ok {
+		t.Fatal("peekErrorCode found an error in a stat=ok response")
+	}
+}